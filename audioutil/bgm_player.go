@@ -0,0 +1,125 @@
+package audioutil
+
+import (
+	"bytes"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+)
+
+// bgmFadeFrames is how many Update ticks a fade-in, fade-out or crossfade
+// takes to complete (~30 frames, about half a second at 60 TPS).
+const bgmFadeFrames = 30.0
+
+// BGMPlayer streams a looping background music track through two layers, a
+// main track and a "rush" track, and crossfades between them. It is kept
+// separate from SoundManager so the music keeps playing across game-mode
+// transitions instead of being recreated with each one-shot SFX.
+type BGMPlayer struct {
+	volume func() float64
+
+	mainPlayer *audio.Player
+	rushPlayer *audio.Player
+
+	mainVolume, mainTarget float64
+	rushVolume, rushTarget float64
+	playing                bool
+}
+
+// NewBGMPlayer decodes mainData and rushData as vorbis-encoded ogg streams,
+// wraps each in an audio.InfiniteLoop and prepares them for playback. volume
+// is consulted every Update to scale both layers, e.g. SoundManager.BGMVolume.
+func NewBGMPlayer(context *audio.Context, volume func() float64, mainData, rushData []byte) (*BGMPlayer, error) {
+	mainPlayer, err := newLoopingPlayer(context, mainData)
+	if err != nil {
+		return nil, err
+	}
+
+	rushPlayer, err := newLoopingPlayer(context, rushData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BGMPlayer{
+		volume:     volume,
+		mainPlayer: mainPlayer,
+		rushPlayer: rushPlayer,
+	}, nil
+}
+
+func newLoopingPlayer(context *audio.Context, data []byte) (*audio.Player, error) {
+	stream, err := vorbis.DecodeWithoutResampling(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return context.NewPlayer(audio.NewInfiniteLoop(stream, stream.Length()))
+}
+
+// Start begins playback, fading the main track in over bgmFadeFrames. It is
+// a no-op if already playing.
+func (b *BGMPlayer) Start() {
+	if b.playing {
+		return
+	}
+	b.playing = true
+	b.mainPlayer.SetVolume(b.volume() * b.mainVolume)
+	b.rushPlayer.SetVolume(b.volume() * b.rushVolume)
+	b.mainPlayer.Play()
+	b.rushPlayer.Play()
+	b.mainTarget = 1
+	b.rushTarget = 0
+}
+
+// Stop fades both layers out over bgmFadeFrames; Update pauses the
+// underlying players once the fade completes.
+func (b *BGMPlayer) Stop() {
+	b.mainTarget = 0
+	b.rushTarget = 0
+}
+
+// SetRush crossfades between the main and rush layers over bgmFadeFrames.
+func (b *BGMPlayer) SetRush(rush bool) {
+	if rush {
+		b.mainTarget = 0
+		b.rushTarget = 1
+	} else {
+		b.mainTarget = 1
+		b.rushTarget = 0
+	}
+}
+
+// Update advances any in-progress fade/crossfade by one tick. Call it once
+// per game tick regardless of game mode so a fade-out started by Stop keeps
+// progressing.
+func (b *BGMPlayer) Update() {
+	if !b.playing {
+		return
+	}
+
+	b.mainVolume = stepToward(b.mainVolume, b.mainTarget, 1/bgmFadeFrames)
+	b.rushVolume = stepToward(b.rushVolume, b.rushTarget, 1/bgmFadeFrames)
+
+	vol := b.volume()
+	b.mainPlayer.SetVolume(vol * b.mainVolume)
+	b.rushPlayer.SetVolume(vol * b.rushVolume)
+
+	if b.mainTarget == 0 && b.rushTarget == 0 && b.mainVolume == 0 && b.rushVolume == 0 {
+		b.mainPlayer.Pause()
+		b.rushPlayer.Pause()
+		b.playing = false
+	}
+}
+
+func stepToward(v, target, step float64) float64 {
+	switch {
+	case v < target:
+		if v += step; v > target {
+			v = target
+		}
+	case v > target:
+		if v -= step; v < target {
+			v = target
+		}
+	}
+	return v
+}
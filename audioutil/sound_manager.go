@@ -0,0 +1,196 @@
+// Package audioutil provides a small sound manager on top of ebiten's audio
+// package, adding per-channel volume control and on-disk persistence of the
+// player's mute/volume preferences.
+package audioutil
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// Channel groups sounds that should be controlled by the same volume slider.
+type Channel int
+
+const (
+	ChannelSFX Channel = iota
+	ChannelBGM
+)
+
+type soundEntry struct {
+	data    []byte
+	channel Channel
+	player  *audio.Player
+}
+
+type volumeState struct {
+	Muted        bool    `json:"muted"`
+	MasterVolume float64 `json:"masterVolume"`
+	SFXVolume    float64 `json:"sfxVolume"`
+	BGMVolume    float64 `json:"bgmVolume"`
+}
+
+// SoundManager holds preloaded sound entries keyed by name and plays them
+// through a shared audio.Context, applying master/SFX/BGM volume levels that
+// persist between runs via a small JSON file under os.UserConfigDir.
+type SoundManager struct {
+	context *audio.Context
+	appName string
+	sounds  map[string]*soundEntry
+	state   volumeState
+}
+
+// NewSoundManager creates a manager bound to context, loading any previously
+// saved volume/mute settings for appName.
+func NewSoundManager(context *audio.Context, appName string) *SoundManager {
+	m := &SoundManager{
+		context: context,
+		appName: appName,
+		sounds:  map[string]*soundEntry{},
+		state: volumeState{
+			MasterVolume: 1,
+			SFXVolume:    1,
+			BGMVolume:    1,
+		},
+	}
+	m.load()
+	return m
+}
+
+// Register adds a decoded sound under key, to be played on channel.
+func (m *SoundManager) Register(key string, data []byte, channel Channel) {
+	m.sounds[key] = &soundEntry{data: data, channel: channel}
+}
+
+func (m *SoundManager) channelVolume(channel Channel) float64 {
+	switch channel {
+	case ChannelBGM:
+		return m.state.BGMVolume
+	default:
+		return m.state.SFXVolume
+	}
+}
+
+func (m *SoundManager) player(key string) *soundEntry {
+	e, ok := m.sounds[key]
+	if !ok {
+		return nil
+	}
+	if e.player == nil {
+		e.player = m.context.NewPlayerFromBytes(e.data)
+	}
+	return e
+}
+
+// Play plays the sound registered under key at full channel volume.
+func (m *SoundManager) Play(key string) {
+	m.PlayWithVolume(key, 1)
+}
+
+// PlayWithVolume plays the sound registered under key, scaled by volume in
+// addition to the master and channel volumes.
+func (m *SoundManager) PlayWithVolume(key string, volume float64) {
+	if m.state.Muted {
+		return
+	}
+	e := m.player(key)
+	if e == nil {
+		return
+	}
+	e.player.Rewind()
+	e.player.SetVolume(m.state.MasterVolume * m.channelVolume(e.channel) * volume)
+	e.player.Play()
+}
+
+// Rewind resets the playback position of the sound registered under key
+// without playing it.
+func (m *SoundManager) Rewind(key string) {
+	if e := m.player(key); e != nil {
+		e.player.Rewind()
+	}
+}
+
+// Muted reports whether all sound is currently muted.
+func (m *SoundManager) Muted() bool {
+	return m.state.Muted
+}
+
+// SetMuted sets the master mute flag and persists it.
+func (m *SoundManager) SetMuted(muted bool) {
+	m.state.Muted = muted
+	m.save()
+}
+
+// MasterVolume and SFXVolume return the current 0-1 volume level for their
+// respective channel.
+func (m *SoundManager) MasterVolume() float64 { return m.state.MasterVolume }
+func (m *SoundManager) SFXVolume() float64    { return m.state.SFXVolume }
+
+// BGMVolume returns the current 0-1 BGM volume level, or 0 when muted. BGM
+// loops continuously via BGMPlayer rather than going through Play/
+// PlayWithVolume, so muting has to be enforced here instead.
+func (m *SoundManager) BGMVolume() float64 {
+	if m.state.Muted {
+		return 0
+	}
+	return m.state.BGMVolume
+}
+
+// SetMasterVolume, SetSFXVolume and SetBGMVolume clamp v to [0, 1] and persist
+// it. The new level is used by the next Play/PlayWithVolume call and by a
+// BGMPlayer reading BGMVolume on its next Update; a sound effect that is
+// already playing keeps the volume it was started with.
+func (m *SoundManager) SetMasterVolume(v float64) { m.state.MasterVolume = clamp01(v); m.save() }
+func (m *SoundManager) SetSFXVolume(v float64)    { m.state.SFXVolume = clamp01(v); m.save() }
+func (m *SoundManager) SetBGMVolume(v float64)    { m.state.BGMVolume = clamp01(v); m.save() }
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func (m *SoundManager) configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, m.appName, "audio.json"), nil
+}
+
+func (m *SoundManager) load() {
+	path, err := m.configPath()
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var state volumeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return
+	}
+	m.state = state
+}
+
+func (m *SoundManager) save() {
+	path, err := m.configPath()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	data, err := json.Marshal(m.state)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0644)
+}
@@ -10,6 +10,7 @@ import (
 	"math"
 	"math/rand/v2"
 	"os"
+	"path/filepath"
 	"sort"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	logging "github.com/tsujio/game-logging-server/client"
+	"github.com/tsujio/game-rhinoceros/audioutil"
 	"github.com/tsujio/game-rhinoceros/effectutil"
 	"github.com/tsujio/game-rhinoceros/loggingutil"
 	resourceutilv2 "github.com/tsujio/game-rhinoceros/resourceutil"
@@ -61,40 +63,149 @@ var (
 	emptySubImage = emptyImage.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
 )
 
-//go:embed resources/secret resources/*.png resources/*.ttf resources/*.dat
+//go:embed resources/secret resources/*.png resources/*.ttf resources/*.dat resources/*.ogg
 var resources embed.FS
 
 var imgLoader = resourceutilv2.NewImageLoader(resources, "resources/rhinoceros.png")
 
+const (
+	soundGameStart = "game-start"
+	soundGameOver  = "game-over"
+	soundHit       = "hit"
+	soundRun       = "run"
+	soundCharge    = "charge"
+)
+
 var (
 	fontFaceL, fontFaceM, fontFaceS = resourceutilv2.ForceLoadFont(resources, "resources/PressStart2P-Regular.ttf")
 	audioContext                    = audio.NewContext(48000)
-	gameStartAudioData              = resourceutil.ForceLoadDecodedAudio(resources, "resources/魔王魂 効果音 システム49.mp3.dat", audioContext)
-	gameOverAudioData               = resourceutil.ForceLoadDecodedAudio(resources, "resources/魔王魂 効果音 システム32.mp3.dat", audioContext)
-	hitAudioData                    = resourceutil.ForceLoadDecodedAudio(resources, "resources/maou_se_battle12.mp3.dat", audioContext)
-	runAudioData                    = resourceutil.ForceLoadDecodedAudio(resources, "resources/maou_se_sound_ignition01.mp3.dat", audioContext)
-	chargeAudioData                 = resourceutil.ForceLoadDecodedAudio(resources, "resources/maou_se_sound17.mp3.dat", audioContext)
+	soundManager                    = audioutil.NewSoundManager(audioContext, gameName)
 	rhinoImg                        = imgLoader.ExtractList(0, 0, 110, 60, 1, 4)
 	rhinoHitImg                     = imgLoader.Extract(0, 240, 110, 70)
 	enemyImg                        = imgLoader.ExtractList(150, 0, 120, 70, 1, 2)
+	batImg                          = imgLoader.ExtractList(150, 80, 100, 60, 1, 2)
+	ghostImg                        = imgLoader.ExtractList(150, 150, 110, 70, 1, 2)
+	bossImg                         = imgLoader.ExtractList(150, 230, 160, 100, 1, 2)
+	shieldImg                       = imgLoader.Extract(150, 330, 40, 40)
+	gaugeBoostImg                   = imgLoader.Extract(200, 330, 40, 40)
+	scoreMultiplierImg              = imgLoader.Extract(250, 330, 40, 40)
 	treeImg                         = imgLoader.Extract(290, 0, 150, 120)
 	weedImg                         = imgLoader.Extract(290, 130, 70, 40)
 	//cloudImgS                       = imgLoader.Extract(290, 190, 80, 40)
-	cloudImgL     = imgLoader.Extract(290, 240, 130, 60)
-	backgroundImg = imgLoader.Extract(560, 0, 60, 480)
+	cloudImgL       = imgLoader.Extract(290, 240, 130, 60)
+	skyImg          = imgLoader.Extract(620, 0, 640, 120)
+	farMountainImg  = imgLoader.Extract(620, 120, 640, 100)
+	midHillImg      = imgLoader.Extract(620, 220, 640, 100)
+	groundDetailImg = imgLoader.Extract(620, 320, 640, 100)
+)
+
+func init() {
+	soundManager.Register(soundGameStart, resourceutil.ForceLoadDecodedAudio(resources, "resources/魔王魂 効果音 システム49.mp3.dat", audioContext), audioutil.ChannelSFX)
+	soundManager.Register(soundGameOver, resourceutil.ForceLoadDecodedAudio(resources, "resources/魔王魂 効果音 システム32.mp3.dat", audioContext), audioutil.ChannelSFX)
+	soundManager.Register(soundHit, resourceutil.ForceLoadDecodedAudio(resources, "resources/maou_se_battle12.mp3.dat", audioContext), audioutil.ChannelSFX)
+	soundManager.Register(soundRun, resourceutil.ForceLoadDecodedAudio(resources, "resources/maou_se_sound_ignition01.mp3.dat", audioContext), audioutil.ChannelSFX)
+	soundManager.Register(soundCharge, resourceutil.ForceLoadDecodedAudio(resources, "resources/maou_se_sound17.mp3.dat", audioContext), audioutil.ChannelSFX)
+}
+
+func forceReadResource(path string) []byte {
+	data, err := resources.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return data
+}
+
+var bgmPlayer = func() *audioutil.BGMPlayer {
+	p, err := audioutil.NewBGMPlayer(audioContext, soundManager.BGMVolume,
+		forceReadResource("resources/bgm_main.ogg"), forceReadResource("resources/bgm_rush.ogg"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return p
+}()
+
+// EnemyKind selects an Enemy's movement pattern, hit radius, score value and
+// sprite, per the behavior table in Enemy's methods.
+type EnemyKind int
+
+const (
+	EnemyKindNormal EnemyKind = iota
+	EnemyKindBat
+	EnemyKindGhost
+	EnemyKindBoss
 )
 
+// bossHP is how many rush impacts a boss enemy takes before it goes down.
+const bossHP = 3
+
 type Enemy struct {
+	kind         EnemyKind
 	ticks        uint64
 	pos, prevPos *mathutil.Vector2D
+	baseY        float64
 	hitV         *mathutil.Vector2D
 	hit          bool
+	hp           int
+	flashTicks   uint64
 	runner       *GameRunner
 }
 
+func newEnemy(kind EnemyKind, pos *mathutil.Vector2D, runner *GameRunner) Enemy {
+	e := Enemy{
+		kind:    kind,
+		pos:     pos,
+		prevPos: pos,
+		baseY:   pos.Y,
+		runner:  runner,
+	}
+	if kind == EnemyKindBoss {
+		e.hp = bossHP
+	}
+	return e
+}
+
+func (e *Enemy) radius() float64 {
+	switch e.kind {
+	case EnemyKindBat:
+		return enemyR * 0.6
+	case EnemyKindBoss:
+		return enemyR * 2.5
+	default:
+		return enemyR
+	}
+}
+
+func (e *Enemy) scoreMultiplier() int {
+	switch e.kind {
+	case EnemyKindGhost:
+		return 2
+	case EnemyKindBoss:
+		return 5
+	default:
+		return 1
+	}
+}
+
+func (e *Enemy) sprite() []*ebiten.Image {
+	switch e.kind {
+	case EnemyKindBat:
+		return batImg
+	case EnemyKindGhost:
+		return ghostImg
+	case EnemyKindBoss:
+		return bossImg
+	default:
+		return enemyImg
+	}
+}
+
 func (e *Enemy) update() {
 	e.ticks++
 
+	if e.flashTicks > 0 {
+		e.flashTicks--
+	}
+
 	if !e.hit {
 		e.prevPos = e.pos
 
@@ -104,7 +215,21 @@ func (e *Enemy) update() {
 		} else {
 			speed += rhinoSpeed
 		}
+
+		switch e.kind {
+		case EnemyKindGhost:
+			if e.pos.X-rhinoX < 200 {
+				speed += 3
+			}
+		case EnemyKindBoss:
+			speed *= 0.6
+		}
+
 		e.pos = e.pos.Add(mathutil.NewVector2D(-speed, 0))
+
+		if e.kind == EnemyKindBat {
+			e.pos = mathutil.NewVector2D(e.pos.X, e.baseY+20*math.Sin(float64(e.ticks)/10))
+		}
 	} else {
 		e.pos = e.pos.Add(e.hitV)
 		e.hitV = e.hitV.Add(mathutil.NewVector2D(0, 1))
@@ -112,8 +237,9 @@ func (e *Enemy) update() {
 }
 
 func (e *Enemy) draw(dst *ebiten.Image) {
-	index := int(e.ticks / 10 % 2)
-	img := enemyImg[index]
+	frames := e.sprite()
+	index := int(e.ticks / 10 % uint64(len(frames)))
+	img := frames[index]
 	size := img.Bounds().Size()
 	opts := &ebiten.DrawImageOptions{}
 	opts.GeoM.Translate(-float64(size.X)/2, -float64(size.Y))
@@ -122,9 +248,109 @@ func (e *Enemy) draw(dst *ebiten.Image) {
 		opts.GeoM.Rotate(2 * math.Pi / 30.0 * float64(e.ticks%30))
 	}
 	opts.GeoM.Translate(e.pos.X, e.pos.Y-float64(index*5))
+	if e.flashTicks > 0 && e.flashTicks%6 >= 3 {
+		opts.ColorScale.Scale(1, 0.3, 0.3, 1)
+	}
+	dst.DrawImage(img, opts)
+}
+
+// PickupKind selects a Pickup's sprite and the effect applied in
+// GameRunner.applyPickup when the rhino touches it.
+type PickupKind int
+
+const (
+	PickupKindShield PickupKind = iota
+	PickupKindGaugeBoost
+	PickupKindScoreMultiplier
+)
+
+const (
+	pickupR              = 10.0
+	scoreMultiplierTicks = 7 * 60
+	scoreMultiplierGain  = 2
+)
+
+type Pickup struct {
+	kind         PickupKind
+	ticks        uint64
+	pos, prevPos *mathutil.Vector2D
+	consumed     bool
+	runner       *GameRunner
+}
+
+func (p *Pickup) img() *ebiten.Image {
+	switch p.kind {
+	case PickupKindShield:
+		return shieldImg
+	case PickupKindScoreMultiplier:
+		return scoreMultiplierImg
+	default:
+		return gaugeBoostImg
+	}
+}
+
+func (p *Pickup) update() {
+	p.ticks++
+	p.prevPos = p.pos
+
+	speed := rhinoSpeed
+	if p.runner.rush {
+		speed = rhinoRushSpeed
+	}
+	p.pos = p.pos.Add(mathutil.NewVector2D(-speed, 0))
+}
+
+func (p *Pickup) draw(dst *ebiten.Image) {
+	img := p.img()
+	size := img.Bounds().Size()
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Translate(-float64(size.X)/2, -float64(size.Y)/2)
+	opts.GeoM.Translate(p.pos.X, p.pos.Y)
 	dst.DrawImage(img, opts)
 }
 
+// dayNightCyclePeriodTicks is how long a full day/night cycle takes, about 2
+// minutes at 60 ticks/sec.
+const dayNightCyclePeriodTicks = 2 * 60 * 60
+
+// Layer is one parallax background strip. It scrolls at speedFactor times
+// the world scroll speed and tiles horizontally, wrapping at its own width.
+type Layer struct {
+	img         *ebiten.Image
+	y           float64
+	speedFactor float64
+	offsetX     float64
+}
+
+func newBackgroundLayers() []Layer {
+	return []Layer{
+		{img: skyImg, y: 0, speedFactor: 0.05},
+		{img: farMountainImg, y: 180, speedFactor: 0.2},
+		{img: midHillImg, y: 260, speedFactor: 0.5},
+		{img: groundDetailImg, y: 380, speedFactor: 1},
+	}
+}
+
+func (l *Layer) update(worldSpeed float64) {
+	width := float64(l.img.Bounds().Dx())
+	l.offsetX = math.Mod(l.offsetX+worldSpeed*l.speedFactor, width)
+	if l.offsetX < 0 {
+		l.offsetX += width
+	}
+}
+
+func (l *Layer) draw(dst *ebiten.Image, tint *ebiten.ColorScale) {
+	width := l.img.Bounds().Dx()
+	for x := -l.offsetX; x < screenWidth; x += float64(width) {
+		opts := &ebiten.DrawImageOptions{}
+		if tint != nil {
+			opts.ColorScale = *tint
+		}
+		opts.GeoM.Translate(x, l.y)
+		dst.DrawImage(l.img, opts)
+	}
+}
+
 type BackgroundObject struct {
 	typ    string
 	pos    *mathutil.Vector3D
@@ -171,18 +397,70 @@ func (o *BackgroundObject) draw(dst *ebiten.Image) {
 }
 
 type GameRunner struct {
-	ticks          uint64
-	game           *Game
-	mute           bool
-	gameOver       bool
-	gage           int
-	rush           bool
-	hitCountInRush int
-	ticksAtHit     uint64
-	enemies        []Enemy
-	objects        []BackgroundObject
-	effects        []effectutil.Effect
-	score          int
+	ticks                   uint64
+	game                    *Game
+	random                  *rand.Rand
+	mute                    bool
+	gameOver                bool
+	gage                    int
+	rush                    bool
+	hitCountInRush          int
+	ticksAtHit              uint64
+	enemies                 []Enemy
+	pickups                 []Pickup
+	backgroundLayers        []Layer
+	objects                 []BackgroundObject
+	effects                 []effectutil.Effect
+	score                   int
+	shielded                bool
+	gainMultiplier          int
+	gainMultiplierTicksLeft uint64
+}
+
+func (r *GameRunner) applyPickup(kind PickupKind) {
+	switch kind {
+	case PickupKindShield:
+		r.shielded = true
+	case PickupKindGaugeBoost:
+		r.gage = gageMax
+	case PickupKindScoreMultiplier:
+		r.gainMultiplier = scoreMultiplierGain
+		r.gainMultiplierTicksLeft = scoreMultiplierTicks
+	}
+}
+
+func (r *GameRunner) playSound(key string) {
+	if !r.mute {
+		soundManager.Play(key)
+	}
+}
+
+func (r *GameRunner) playSoundWithVolume(key string, volume float64) {
+	if !r.mute {
+		soundManager.PlayWithVolume(key, volume)
+	}
+}
+
+// bossIntervalTicks is how often (at 60 ticks/sec, every 30s) a boss enemy
+// is spawned in addition to the regular weighted spawns.
+const bossIntervalTicks = 60 * 30
+
+// spawnEnemy picks a regular enemy kind, weighting bat/ghost odds up as
+// r.ticks grows so the game ramps in difficulty the longer a run lasts.
+func (r *GameRunner) spawnEnemy() Enemy {
+	kind := EnemyKindNormal
+	roll := r.random.Float64()
+	batChance := math.Min(0.15+float64(r.ticks)/6000, 0.35)
+	ghostChance := math.Min(0.1+float64(r.ticks)/9000, 0.3)
+	switch {
+	case roll < batChance:
+		kind = EnemyKindBat
+	case roll < batChance+ghostChance:
+		kind = EnemyKindGhost
+	}
+
+	pos := mathutil.NewVector2D(screenWidth+50, rhinoY)
+	return newEnemy(kind, pos, r)
 }
 
 func (r *GameRunner) createBackgroundObject(typ string, screenX float64) *BackgroundObject {
@@ -197,7 +475,7 @@ func (r *GameRunner) createBackgroundObject(typ string, screenX float64) *Backgr
 		zOffset = 9999
 		zRange = 99999
 	}
-	wPos := mathutil.NewVector3D(0, y, zOffset+r.game.random.Float64()*zRange)
+	wPos := mathutil.NewVector3D(0, y, zOffset+r.random.Float64()*zRange)
 	sPos := mathutil.ConvertCoordinateWorldToScreen(wPos, cameraY, screenZ, screenWidth, screenHeight)
 	sPos.X = screenX
 	pos := mathutil.ConvertCoordinateScreenToWorld(
@@ -222,11 +500,18 @@ func (r *GameRunner) update(touches []touchutil.Touch) {
 
 	r.ticks++
 
+	if r.gainMultiplierTicksLeft > 0 {
+		r.gainMultiplierTicksLeft--
+		if r.gainMultiplierTicksLeft == 0 {
+			r.gainMultiplier = 1
+		}
+	}
+
 	if !r.rush {
 		if touchutil.AnyTouchesJustTouched(touches) ||
 			(r.gage > 0 && touchutil.AnyTouchesActive(touches)) {
-			if !r.mute && r.gage == 0 {
-				audioContext.NewPlayerFromBytes(chargeAudioData).Play()
+			if r.gage == 0 {
+				r.playSound(soundCharge)
 			}
 
 			r.gage += 1
@@ -239,10 +524,8 @@ func (r *GameRunner) update(touches []touchutil.Touch) {
 			r.rush = true
 		}
 
-		if !r.mute && r.ticks%20 == 0 {
-			p := audioContext.NewPlayerFromBytes(runAudioData)
-			p.SetVolume(0.1)
-			p.Play()
+		if r.ticks%20 == 0 {
+			r.playSoundWithVolume(soundRun, 0.1)
 		}
 	} else {
 		r.gage -= 1
@@ -261,7 +544,7 @@ func (r *GameRunner) update(touches []touchutil.Touch) {
 					AngleMax:        math.Pi * 4 / 3,
 					Speed:           8,
 					Ay:              0.3,
-					Random:          r.game.random,
+					Random:          r.random,
 				},
 			))
 		}
@@ -272,24 +555,30 @@ func (r *GameRunner) update(touches []touchutil.Touch) {
 			r.hitCountInRush = 0
 		}
 
-		if !r.mute && r.ticks%10 == 0 {
-			p := audioContext.NewPlayerFromBytes(runAudioData)
-			p.SetVolume(0.1)
-			p.Play()
+		if r.ticks%10 == 0 {
+			r.playSoundWithVolume(soundRun, 0.1)
 		}
 	}
 
-	if r.ticks > 120 && r.game.random.Int()%60 == 0 {
+	if r.ticks > 120 && r.random.Int()%60 == 0 {
+		r.enemies = append(r.enemies, r.spawnEnemy())
+	}
+
+	if r.ticks > 0 && r.ticks%bossIntervalTicks == 0 {
+		r.enemies = append(r.enemies, newEnemy(EnemyKindBoss, mathutil.NewVector2D(screenWidth+50, rhinoY), r))
+	}
+
+	if r.ticks > 120 && r.random.Int()%180 == 0 {
 		pos := mathutil.NewVector2D(screenWidth+50, rhinoY)
-		e := Enemy{
+		r.pickups = append(r.pickups, Pickup{
+			kind:    PickupKind(r.random.Int() % 3),
 			pos:     pos,
 			prevPos: pos,
 			runner:  r,
-		}
-		r.enemies = append(r.enemies, e)
+		})
 	}
 
-	if r.game.random.Int()%60 == 0 {
+	if r.random.Int()%60 == 0 {
 		o := r.createBackgroundObject("tree", screenWidth+100)
 		r.objects = append(r.objects, *o)
 	}
@@ -299,7 +588,7 @@ func (r *GameRunner) update(touches []touchutil.Touch) {
 		r.objects = append(r.objects, *o)
 	}
 
-	if r.game.random.Int()%60 == 0 {
+	if r.random.Int()%60 == 0 {
 		o := r.createBackgroundObject("cloud", screenWidth+100)
 		r.objects = append(r.objects, *o)
 	}
@@ -307,55 +596,66 @@ func (r *GameRunner) update(touches []touchutil.Touch) {
 	for i := range r.enemies {
 		e := &r.enemies[i]
 
-		if !e.hit && mathutil.CapsulesCollide(
+		if !e.hit && e.flashTicks == 0 && mathutil.CapsulesCollide(
 			mathutil.NewVector2D(rhinoX, rhinoY),
 			mathutil.NewVector2D(0, 0),
 			rhinoR,
 			e.prevPos,
 			e.pos.Sub(e.prevPos),
-			enemyR,
+			e.radius(),
 		) {
 			if r.rush {
+				if e.kind == EnemyKindBoss && e.hp > 1 {
+					e.hp--
+					e.flashTicks = 20
+					r.playSound(soundHit)
+				} else {
+					e.hitV = mathutil.NewVector2D(
+						5+10*r.random.Float64(),
+						-(10 + 20*r.random.Float64()),
+					)
+					e.hit = true
+					r.ticksAtHit = r.ticks
+					r.hitCountInRush++
+					gain := 10 * r.hitCountInRush * e.scoreMultiplier() * r.gainMultiplier
+					r.score += gain
+
+					r.effects = append(r.effects, effectutil.NewGainEffect(
+						rhinoX+50+(r.random.Float64()-0.5)*100,
+						rhinoY-30+(r.random.Float64()-0.5)*100,
+						60,
+						&effectutil.GainEffectOptions{
+							Gain: gain,
+							Face: fontFaceM,
+						},
+					))
+
+					r.effects = append(r.effects, effectutil.NewSplashEffect(
+						rhinoX+50,
+						rhinoY-30,
+						999,
+						&effectutil.SplashEffectOptions{
+							Count:           5,
+							Color:           color.RGBA{0xff, 0xff, 0, 0xff},
+							Size:            10,
+							AngularVelocity: math.Pi / 20,
+							AngleMin:        -math.Pi / 2,
+							AngleMax:        math.Pi / 4,
+							Speed:           10,
+							Ay:              0.2,
+							Random:          r.random,
+						},
+					))
+
+					r.playSound(soundHit)
+				}
+			} else if r.shielded {
+				r.shielded = false
 				e.hitV = mathutil.NewVector2D(
-					5+10*r.game.random.Float64(),
-					-(10 + 20*r.game.random.Float64()),
+					5+10*r.random.Float64(),
+					-(10 + 20*r.random.Float64()),
 				)
 				e.hit = true
-				r.ticksAtHit = r.ticks
-				r.hitCountInRush++
-				gain := 10 * r.hitCountInRush
-				r.score += gain
-
-				r.effects = append(r.effects, effectutil.NewGainEffect(
-					rhinoX+50+(r.game.random.Float64()-0.5)*100,
-					rhinoY-30+(r.game.random.Float64()-0.5)*100,
-					60,
-					&effectutil.GainEffectOptions{
-						Gain: gain,
-						Face: fontFaceM,
-					},
-				))
-
-				r.effects = append(r.effects, effectutil.NewSplashEffect(
-					rhinoX+50,
-					rhinoY-30,
-					999,
-					&effectutil.SplashEffectOptions{
-						Count:           5,
-						Color:           color.RGBA{0xff, 0xff, 0, 0xff},
-						Size:            10,
-						AngularVelocity: math.Pi / 20,
-						AngleMin:        -math.Pi / 2,
-						AngleMax:        math.Pi / 4,
-						Speed:           10,
-						Ay:              0.2,
-						Random:          r.game.random,
-					},
-				))
-
-				if !r.mute {
-					audioContext.NewPlayerFromBytes(hitAudioData).Play()
-				}
 			} else {
 				r.gameOver = true
 			}
@@ -364,10 +664,53 @@ func (r *GameRunner) update(touches []touchutil.Touch) {
 		e.update()
 	}
 
+	for i := range r.pickups {
+		p := &r.pickups[i]
+
+		if !p.consumed && mathutil.CapsulesCollide(
+			mathutil.NewVector2D(rhinoX, rhinoY),
+			mathutil.NewVector2D(0, 0),
+			rhinoR,
+			p.prevPos,
+			p.pos.Sub(p.prevPos),
+			pickupR,
+		) {
+			p.consumed = true
+			r.applyPickup(p.kind)
+
+			r.effects = append(r.effects, effectutil.NewSplashEffect(
+				p.pos.X,
+				p.pos.Y,
+				20,
+				&effectutil.SplashEffectOptions{
+					Count:           4,
+					Color:           color.White,
+					Size:            8,
+					AngularVelocity: math.Pi / 20,
+					AngleMin:        0,
+					AngleMax:        2 * math.Pi,
+					Speed:           6,
+					Ay:              0.2,
+					Random:          r.random,
+				},
+			))
+		}
+
+		p.update()
+	}
+
 	for i := range r.objects {
 		r.objects[i].update()
 	}
 
+	worldSpeed := rhinoSpeed
+	if r.rush {
+		worldSpeed = rhinoRushSpeed
+	}
+	for i := range r.backgroundLayers {
+		r.backgroundLayers[i].update(worldSpeed)
+	}
+
 	for i := range r.effects {
 		r.effects[i].Update()
 	}
@@ -380,6 +723,14 @@ func (r *GameRunner) update(touches []touchutil.Touch) {
 	}
 	r.enemies = _enemies
 
+	_pickups := r.pickups[:0]
+	for i := range r.pickups {
+		if !r.pickups[i].consumed && r.pickups[i].pos.X > -100 {
+			_pickups = append(_pickups, r.pickups[i])
+		}
+	}
+	r.pickups = _pickups
+
 	_objects := r.objects[:0]
 	for i := range r.objects {
 		pos := mathutil.ConvertCoordinateWorldToScreen(r.objects[i].pos, cameraY, screenZ, screenWidth, screenHeight)
@@ -402,12 +753,20 @@ func (r *GameRunner) update(touches []touchutil.Touch) {
 	})
 }
 
+// drawBackground draws the parallax layers back-to-front, tinting the
+// furthest (sky) layer to shift brightness over a day/night cycle.
 func (r *GameRunner) drawBackground(dst *ebiten.Image) {
-	size := backgroundImg.Bounds().Size()
-	for x := 0; x < screenWidth; x += size.X {
-		opts := &ebiten.DrawImageOptions{}
-		opts.GeoM.Translate(float64(x), 0)
-		dst.DrawImage(backgroundImg, opts)
+	phase := 2 * math.Pi * float64(r.ticks) / dayNightCyclePeriodTicks
+	brightness := float32(0.6 + 0.4*(0.5+0.5*math.Cos(phase)))
+	skyTint := ebiten.ColorScale{}
+	skyTint.Scale(brightness, brightness, brightness+(1-brightness)*0.5, 1)
+
+	for i := range r.backgroundLayers {
+		var tint *ebiten.ColorScale
+		if i == 0 {
+			tint = &skyTint
+		}
+		r.backgroundLayers[i].draw(dst, tint)
 	}
 }
 
@@ -468,6 +827,61 @@ func (r *GameRunner) drawGage(dst *ebiten.Image) {
 	dst.DrawTriangles(vs, is, emptySubImage, opts)
 }
 
+// drawHUDIcon draws img at (x, y) and, if frac < 1, a shrinking radial timer
+// ring around it showing the remaining fraction of the item's duration.
+func drawHUDIcon(dst *ebiten.Image, x, y float64, img *ebiten.Image, frac float64) {
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Translate(x, y)
+	dst.DrawImage(img, opts)
+
+	if frac >= 1 {
+		return
+	}
+
+	size := img.Bounds().Size()
+	const margin = 4.0
+	cx, cy := float32(x+float64(size.X)/2), float32(y+float64(size.Y)/2)
+	radius := float32(size.X)/2 + margin
+
+	var path vector.Path
+	path.MoveTo(cx, cy-radius)
+	path.Arc(cx, cy, radius, -math.Pi/2, float32(-math.Pi/2+2*math.Pi*frac), vector.Clockwise)
+
+	op := &vector.StrokeOptions{}
+	op.Width = 3
+	op.LineJoin = vector.LineJoinRound
+	vs, is := path.AppendVerticesAndIndicesForStroke(nil, nil, op)
+
+	for i := range vs {
+		vs[i].SrcX = 1
+		vs[i].SrcY = 1
+		vs[i].ColorR, vs[i].ColorG, vs[i].ColorB, vs[i].ColorA = 1, 1, 1, 1
+	}
+
+	dst.DrawTriangles(vs, is, emptySubImage, &ebiten.DrawTrianglesOptions{})
+}
+
+// drawHUD draws a strip of active-item icons, each with a shrinking radial
+// timer where the item has a duration (shield persists until consumed, so it
+// has none).
+func (r *GameRunner) drawHUD(dst *ebiten.Image) {
+	const (
+		iconX = 20.0
+		iconY = 20.0
+		gap   = 50.0
+	)
+
+	x := iconX
+	if r.shielded {
+		drawHUDIcon(dst, x, iconY, shieldImg, 1)
+		x += gap
+	}
+	if r.gainMultiplier > 1 {
+		drawHUDIcon(dst, x, iconY, scoreMultiplierImg, float64(r.gainMultiplierTicksLeft)/scoreMultiplierTicks)
+		x += gap
+	}
+}
+
 func (r *GameRunner) draw(dst *ebiten.Image) {
 	r.drawBackground(dst)
 
@@ -481,10 +895,16 @@ func (r *GameRunner) draw(dst *ebiten.Image) {
 
 	r.drawGage(dst)
 
+	r.drawHUD(dst)
+
 	for i := range r.enemies {
 		r.enemies[i].draw(dst)
 	}
 
+	for i := range r.pickups {
+		r.pickups[i].draw(dst)
+	}
+
 	for i := range r.objects {
 		if r.objects[i].pos.Z <= rhinoZ {
 			r.objects[i].draw((dst))
@@ -502,8 +922,156 @@ const (
 	GameModeTitle GameMode = iota
 	GameModePlaying
 	GameModeGameOver
+	GameModeReplay
 )
 
+// replayPath returns the file the best-score touch recording is persisted
+// to, under the OS's per-user config directory.
+func replayPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, gameName, "best_replay.json")
+}
+
+// settingsSlider is a single +/- row in the SettingsOverlay, bound to one of
+// the SoundManager's volume channels.
+type settingsSlider struct {
+	label    string
+	minusBtn image.Rectangle
+	plusBtn  image.Rectangle
+	get      func() float64
+	set      func(float64)
+}
+
+// SettingsOverlay is a touch-driven panel, toggled via a gear button on the
+// title screen, that lets the player adjust the master/SFX/BGM volume and
+// mute, backed by the package-level soundManager.
+type SettingsOverlay struct {
+	visible  bool
+	gearRect image.Rectangle
+	muteRect image.Rectangle
+	sliders  []settingsSlider
+}
+
+func newSettingsOverlay() *SettingsOverlay {
+	o := &SettingsOverlay{
+		gearRect: image.Rect(10, 10, 40, 40),
+		muteRect: image.Rect(screenWidth/2-60, 330, screenWidth/2+60, 360),
+	}
+	rowY := 170
+	for _, s := range []struct {
+		label string
+		get   func() float64
+		set   func(float64)
+	}{
+		{"MASTER", soundManager.MasterVolume, soundManager.SetMasterVolume},
+		{"SFX", soundManager.SFXVolume, soundManager.SetSFXVolume},
+		{"BGM", soundManager.BGMVolume, soundManager.SetBGMVolume},
+	} {
+		o.sliders = append(o.sliders, settingsSlider{
+			label:    s.label,
+			minusBtn: image.Rect(screenWidth/2-100, rowY, screenWidth/2-70, rowY+30),
+			plusBtn:  image.Rect(screenWidth/2+70, rowY, screenWidth/2+100, rowY+30),
+			get:      s.get,
+			set:      s.set,
+		})
+		rowY += 50
+	}
+	return o
+}
+
+func settingsRectContains(rect image.Rectangle, pos mathutil.Vector2D) bool {
+	return pos.X >= float64(rect.Min.X) && pos.X < float64(rect.Max.X) &&
+		pos.Y >= float64(rect.Min.Y) && pos.Y < float64(rect.Max.Y)
+}
+
+// ghostButtonTapped reports whether any just-touched touch landed on the
+// title screen's GHOST button.
+func ghostButtonTapped(touches []touchutil.Touch) bool {
+	for _, t := range touches {
+		if t.IsJustTouched() && settingsRectContains(ghostButtonRect, t.Position()) {
+			return true
+		}
+	}
+	return false
+}
+
+// update applies any just-touched taps and reports whether it consumed the
+// touches, so the caller can skip other handling (e.g. starting the game)
+// for this tick.
+func (o *SettingsOverlay) update(touches []touchutil.Touch) bool {
+	consumed := false
+	for _, t := range touches {
+		if !t.IsJustTouched() {
+			continue
+		}
+
+		pos := t.Position()
+		if settingsRectContains(o.gearRect, pos) {
+			o.visible = !o.visible
+			consumed = true
+			continue
+		}
+
+		if !o.visible {
+			continue
+		}
+
+		// The panel is modal while visible: any tap on it is consumed here,
+		// even one that misses every button, so it can't fall through to
+		// the title screen's tap-to-start.
+		consumed = true
+
+		if settingsRectContains(o.muteRect, pos) {
+			soundManager.SetMuted(!soundManager.Muted())
+			continue
+		}
+
+		for _, s := range o.sliders {
+			switch {
+			case settingsRectContains(s.minusBtn, pos):
+				s.set(s.get() - 0.1)
+			case settingsRectContains(s.plusBtn, pos):
+				s.set(s.get() + 0.1)
+			}
+		}
+	}
+	return consumed
+}
+
+func drawSettingsButton(dst *ebiten.Image, rect image.Rectangle, label string) {
+	x, y := float32(rect.Min.X), float32(rect.Min.Y)
+	w, h := float32(rect.Dx()), float32(rect.Dy())
+	vector.StrokeRect(dst, x, y, w, h, 2, color.White, true)
+	resourceutilv2.DrawTextWithFace(dst, label,
+		rect.Min.X+rect.Dx()/2, rect.Min.Y+rect.Dy()/2-6, text.AlignCenter, color.White, fontFaceS, 0)
+}
+
+func (o *SettingsOverlay) draw(dst *ebiten.Image) {
+	drawSettingsButton(dst, o.gearRect, "*")
+
+	if !o.visible {
+		return
+	}
+
+	vector.DrawFilledRect(dst, 60, 140, screenWidth-120, 250, color.RGBA{0, 0, 0, 0xc0}, true)
+
+	for _, s := range o.sliders {
+		drawSettingsButton(dst, s.minusBtn, "-")
+		drawSettingsButton(dst, s.plusBtn, "+")
+		resourceutilv2.DrawTextWithFace(dst, fmt.Sprintf("%s %d%%", s.label, int(s.get()*100)),
+			screenWidth/2, s.minusBtn.Min.Y+15-6, text.AlignCenter, color.White, fontFaceS, 0)
+	}
+
+	muteLabel := "MUTE"
+	if soundManager.Muted() {
+		muteLabel = "MUTED"
+	}
+	drawSettingsButton(dst, o.muteRect, muteLabel)
+}
+
 type Game struct {
 	playerID        string
 	sessionID       string
@@ -516,6 +1084,71 @@ type Game struct {
 	runner          *GameRunner
 	highScore       int
 	debug           bool
+	settings        *SettingsOverlay
+
+	recorder *touchutil.TouchRecorder
+
+	ghostReplay *touchutil.ReplayPlayer
+	ghostRunner *GameRunner
+	ghostImage  *ebiten.Image
+
+	replay       *touchutil.ReplayPlayer
+	replayRunner *GameRunner
+}
+
+// saveReplayIfBest persists the just-finished play's recording as the new
+// personal-best ghost if it beat (or there is no) existing best.
+func (g *Game) saveReplayIfBest() {
+	path := replayPath()
+	if path == "" || g.recorder == nil {
+		return
+	}
+
+	best := -1
+	if p, err := touchutil.LoadReplayPlayer(path); err == nil {
+		best = p.Score()
+	}
+
+	if g.runner.score > best {
+		if err := g.recorder.Save(path, g.runner.score); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// startGhostRunner loads the best-score replay, if any, so it can be played
+// back concurrently with the live run in GameModePlaying. The ghost is given
+// its own *rand.Rand seeded from the recording, independent of g.random, so
+// it regenerates the same world its touches were recorded against instead of
+// racing the live run through a shared RNG stream.
+func (g *Game) startGhostRunner() {
+	g.ghostReplay = nil
+	g.ghostRunner = nil
+
+	replay, err := touchutil.LoadReplayPlayer(replayPath())
+	if err != nil {
+		return
+	}
+	g.ghostReplay = replay
+	seed := uint64(replay.RandomSeed())
+	g.ghostRunner = g.newGameRunner(true, rand.New(rand.NewPCG(seed, seed)))
+}
+
+// startReplayMode loads the best-score replay into a dedicated GameRunner
+// and switches to GameModeReplay, reseeding g.random from the recording so
+// the replayed run is deterministic.
+func (g *Game) startReplayMode() bool {
+	replay, err := touchutil.LoadReplayPlayer(replayPath())
+	if err != nil {
+		return false
+	}
+
+	g.random = rand.New(rand.NewPCG(uint64(replay.RandomSeed()), uint64(replay.RandomSeed())))
+	g.replay = replay
+	g.replayRunner = g.newGameRunner(true, g.random)
+	g.setNextMode(GameModeReplay)
+
+	return true
 }
 
 func (g *Game) Update() error {
@@ -525,26 +1158,48 @@ func (g *Game) Update() error {
 
 	loggingutil.SendTouchLog(gameName, g.playerID, g.sessionID, g.playID, g.modeTicks, g.touches)
 
+	bgmPlayer.Update()
+
 	switch g.mode {
 	case GameModeTitle:
 		touches := g.touchSimulation.Next()
 		g.runner.update(touches)
+		bgmPlayer.SetRush(g.runner.rush)
 		if g.runner.gameOver {
-			g.runner = g.newGameRunner(true)
+			g.runner = g.newGameRunner(true, g.random)
 			g.touchSimulation = g.generateTouchSimulation()
 		}
 
-		if touchutil.AnyTouchesJustTouched(g.touches) {
+		settingsConsumed := g.settings.update(g.touches)
+
+		ghostTapped := !settingsConsumed && ghostButtonTapped(g.touches) && g.startReplayMode()
+
+		if !settingsConsumed && !ghostTapped && touchutil.AnyTouchesJustTouched(g.touches) {
 			loggingutil.SendLog(gameName, g.playerID, g.sessionID, g.playID, &loggingutil.StartGamePayload{})
 
-			g.runner = g.newGameRunner(false)
+			// g.random has already been advanced through the title-screen
+			// demo, so reseed it here with a fresh seed and record that
+			// seed rather than the stale one from initialize(); otherwise
+			// a replay reseeded from the old seed would desync from the
+			// very first recorded tick.
+			playSeed := time.Now().UnixNano()
+			g.random = rand.New(rand.NewPCG(uint64(playSeed), uint64(playSeed)))
+			g.runner = g.newGameRunner(false, g.random)
+			g.recorder = touchutil.NewTouchRecorder(playSeed)
+			g.startGhostRunner()
 
 			g.setNextMode(GameModePlaying)
 
-			audioContext.NewPlayerFromBytes(gameStartAudioData).Play()
+			soundManager.Play(soundGameStart)
 		}
 	case GameModePlaying:
 		g.runner.update(g.touches)
+		bgmPlayer.SetRush(g.runner.rush)
+		g.recorder.Record(g.modeTicks, g.touches)
+
+		if g.ghostRunner != nil {
+			g.ghostRunner.update(g.ghostReplay.Next())
+		}
 
 		if g.runner.score > g.highScore {
 			g.highScore = g.runner.score
@@ -555,22 +1210,55 @@ func (g *Game) Update() error {
 
 			g.setNextMode(GameModeGameOver)
 
-			audioContext.NewPlayerFromBytes(gameOverAudioData).Play()
+			soundManager.Play(soundGameOver)
+			bgmPlayer.Stop()
+
+			g.saveReplayIfBest()
 		}
 	case GameModeGameOver:
 		if g.modeTicks > 60 && touchutil.AnyTouchesJustTouched(g.touches) {
 			g.initialize()
 		}
+	case GameModeReplay:
+		g.replayRunner.update(g.replay.Next())
+
+		if g.replay.Finished() || touchutil.AnyTouchesJustTouched(g.touches) {
+			g.setNextMode(GameModeTitle)
+		}
 	}
 
 	return nil
 }
 
+// drawGhost renders the best-score ghost run, translucent, over the live
+// game so players can race their own personal best.
+func (g *Game) drawGhost(dst *ebiten.Image) {
+	if g.ghostRunner == nil {
+		return
+	}
+
+	if g.ghostImage == nil {
+		g.ghostImage = ebiten.NewImage(screenWidth, screenHeight)
+	}
+	g.ghostImage.Clear()
+	g.ghostRunner.draw(g.ghostImage)
+
+	opts := &ebiten.DrawImageOptions{}
+	opts.ColorScale.ScaleAlpha(0.4)
+	dst.DrawImage(g.ghostImage, opts)
+
+	resourceutilv2.DrawTextWithFace(dst, "GHOST",
+		screenWidth-10, 30, text.AlignEnd, color.White, fontFaceS, 0)
+}
+
 func (g *Game) drawScore(dst *ebiten.Image) {
 	resourceutilv2.DrawTextWithFace(dst, fmt.Sprintf("SCORE %d HI %d", g.runner.score, g.highScore),
 		screenWidth-10, 10, text.AlignEnd, color.White, fontFaceS, 0)
 }
 
+// ghostButtonRect is the title-screen tap target that starts GameModeReplay.
+var ghostButtonRect = image.Rect(screenWidth-70, 10, screenWidth-10, 40)
+
 func (g *Game) drawTitle(dst *ebiten.Image) {
 	resourceutilv2.DrawTextWithFace(dst, "RHINOCEROS",
 		screenWidth/2, 120, text.AlignCenter, color.RGBA{0, 0, 0x50, 0xff}, fontFaceL, 0)
@@ -580,6 +1268,8 @@ func (g *Game) drawTitle(dst *ebiten.Image) {
 
 	resourceutilv2.DrawTextWithFace(dst, "CREATOR: NAOKI TSUJIO\nFONT: Press Start 2P by CodeMan38\nSOUND EFFECT: MaouDamashii",
 		screenWidth/2, 410, text.AlignCenter, color.RGBA{0, 0, 0x50, 0xff}, fontFaceS, 1.8)
+
+	drawSettingsButton(dst, ghostButtonRect, "GHOST")
 }
 
 func (g *Game) drawGameOver(dst *ebiten.Image) {
@@ -595,13 +1285,19 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	case GameModeTitle:
 		g.runner.draw(screen)
 		g.drawTitle(screen)
+		g.settings.draw(screen)
 	case GameModePlaying:
 		g.runner.draw(screen)
+		g.drawGhost(screen)
 		g.drawScore(screen)
 	case GameModeGameOver:
 		g.runner.draw(screen)
 		g.drawScore(screen)
 		g.drawGameOver(screen)
+	case GameModeReplay:
+		g.replayRunner.draw(screen)
+		resourceutilv2.DrawTextWithFace(screen, "GHOST REPLAY",
+			screenWidth/2, 20, text.AlignCenter, color.White, fontFaceS, 0)
 	}
 
 	if g.debug {
@@ -629,23 +1325,26 @@ func (g *Game) generateTouchSimulation() *touchutil.TouchSimulation {
 	return s
 }
 
-func (g *Game) newGameRunner(mute bool) *GameRunner {
-	runner := &GameRunner{game: g, mute: mute}
+// newGameRunner creates a GameRunner driven by random. The live runner and
+// any replay/ghost runner running alongside it must each get their own
+// *rand.Rand so one run's RNG consumption never perturbs another's.
+func (g *Game) newGameRunner(mute bool, random *rand.Rand) *GameRunner {
+	runner := &GameRunner{game: g, mute: mute, random: random, gainMultiplier: 1, backgroundLayers: newBackgroundLayers()}
 
 	for i := 0; i < 99; i++ {
-		x := g.random.Float64() * screenWidth
+		x := random.Float64() * screenWidth
 		o := runner.createBackgroundObject("tree", x)
 		runner.objects = append(runner.objects, *o)
 	}
 
 	for i := 0; i < 99; i++ {
-		x := g.random.Float64() * screenWidth
+		x := random.Float64() * screenWidth
 		o := runner.createBackgroundObject("weed", x)
 		runner.objects = append(runner.objects, *o)
 	}
 
 	for i := 0; i < 20; i++ {
-		x := g.random.Float64() * screenWidth
+		x := random.Float64() * screenWidth
 		o := runner.createBackgroundObject("cloud", x)
 		runner.objects = append(runner.objects, *o)
 	}
@@ -667,7 +1366,13 @@ func (g *Game) initialize() {
 	g.random = rand.New(rand.NewPCG(uint64(seed), uint64(seed)))
 	g.touches = nil
 	g.touchSimulation = g.generateTouchSimulation()
-	g.runner = g.newGameRunner(true)
+	g.runner = g.newGameRunner(true, g.random)
+
+	if g.settings == nil {
+		g.settings = newSettingsOverlay()
+	}
+
+	bgmPlayer.Start()
 
 	g.setNextMode(GameModeTitle)
 }
@@ -0,0 +1,131 @@
+package touchutil
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/tsujio/game-util/mathutil"
+)
+
+// NewTouch constructs a synthetic Touch for replay playback, reporting
+// position and justTouched exactly as recorded.
+func NewTouch(position mathutil.Vector2D, justTouched bool) Touch {
+	return Touch{position: position, justTouched: justTouched}
+}
+
+// recordedTouch is the JSON-serializable mirror of a Touch captured for
+// replay; only the fields a replay needs to reproduce are kept.
+type recordedTouch struct {
+	X, Y        float64
+	JustTouched bool `json:"justTouched"`
+}
+
+type recordedFrame struct {
+	ModeTicks uint64          `json:"modeTicks"`
+	Touches   []recordedTouch `json:"touches"`
+}
+
+type recording struct {
+	RandomSeed int64           `json:"randomSeed"`
+	Score      int             `json:"score"`
+	Frames     []recordedFrame `json:"frames"`
+}
+
+// TouchRecorder captures (modeTicks, touches, randomSeed) for a single play
+// so it can be saved to a file under the user config dir and later fed back
+// through a ReplayPlayer.
+type TouchRecorder struct {
+	randomSeed int64
+	frames     []recordedFrame
+}
+
+// NewTouchRecorder starts a recording for a play seeded with randomSeed.
+func NewTouchRecorder(randomSeed int64) *TouchRecorder {
+	return &TouchRecorder{randomSeed: randomSeed}
+}
+
+// Record appends the touches active on modeTicks to the recording.
+func (r *TouchRecorder) Record(modeTicks uint64, touches []Touch) {
+	frame := recordedFrame{ModeTicks: modeTicks}
+	for _, t := range touches {
+		pos := t.Position()
+		frame.Touches = append(frame.Touches, recordedTouch{
+			X:           pos.X,
+			Y:           pos.Y,
+			JustTouched: t.IsJustTouched(),
+		})
+	}
+	r.frames = append(r.frames, frame)
+}
+
+// Save writes the recording, tagged with score, to path, creating any
+// missing parent directories.
+func (r *TouchRecorder) Save(path string, score int) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(recording{
+		RandomSeed: r.randomSeed,
+		Score:      score,
+		Frames:     r.frames,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReplayPlayer reconstructs the touches recorded for each tick of a previous
+// play, read back from a file written by TouchRecorder.Save.
+type ReplayPlayer struct {
+	randomSeed int64
+	score      int
+	frames     []recordedFrame
+	cursor     int
+}
+
+// LoadReplayPlayer reads a recording previously saved by TouchRecorder.Save.
+func LoadReplayPlayer(path string) (*ReplayPlayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	return &ReplayPlayer{randomSeed: rec.RandomSeed, score: rec.Score, frames: rec.Frames}, nil
+}
+
+// RandomSeed returns the seed the recorded play was generated with, so a
+// replaying Game can reseed its random source for a deterministic ghost.
+func (p *ReplayPlayer) RandomSeed() int64 {
+	return p.randomSeed
+}
+
+// Score returns the recorded play's final score.
+func (p *ReplayPlayer) Score() int {
+	return p.score
+}
+
+// Next returns the touches recorded for the next tick, or nil once the
+// recording is exhausted.
+func (p *ReplayPlayer) Next() []Touch {
+	if p.cursor >= len(p.frames) {
+		return nil
+	}
+	frame := p.frames[p.cursor]
+	p.cursor++
+
+	touches := make([]Touch, len(frame.Touches))
+	for i, t := range frame.Touches {
+		touches[i] = NewTouch(*mathutil.NewVector2D(t.X, t.Y), t.JustTouched)
+	}
+	return touches
+}
+
+// Finished reports whether the replay has played back its last frame.
+func (p *ReplayPlayer) Finished() bool {
+	return p.cursor >= len(p.frames)
+}